@@ -0,0 +1,9 @@
+package auth
+
+// AuthPipeline represents the context of an auth request, as consumed by identity, metadata and
+// authorization evaluators.
+type AuthPipeline interface {
+	// GetAuthorizationJSON returns the JSON-encoded representation of the data resolved so far in the
+	// pipeline (context, identity, metadata), as consumed by JSONPatternMatching selectors.
+	GetAuthorizationJSON() string
+}
@@ -0,0 +1,50 @@
+package authorization
+
+import (
+	"fmt"
+
+	"github.com/kuadrant/authorino/pkg/auth"
+	"github.com/kuadrant/authorino/pkg/json"
+
+	"golang.org/x/net/context"
+)
+
+// JSONPatternMatching is an authorization policy that checks a list of JSONPatternMatchingRule conditions
+// against the JSON representation of the auth pipeline data (AuthPipeline.GetAuthorizationJSON). The
+// request is authorized only when every rule evaluates to true.
+type JSONPatternMatching struct {
+	Rules []json.JSONPatternMatchingRule
+}
+
+// NewJSONPatternMatching compiles every rule's Value as a template up front (see
+// JSONPatternMatchingRule.Compile), so a malformed template is rejected at config load instead of
+// surfacing as a runtime error on live traffic. Config loading should build a JSONPatternMatching through
+// this constructor rather than the struct literal.
+func NewJSONPatternMatching(rules []json.JSONPatternMatchingRule) (*JSONPatternMatching, error) {
+	for i := range rules {
+		if err := rules[i].Compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &JSONPatternMatching{Rules: rules}, nil
+}
+
+// Call implements common.AuthConfigEvaluator.
+func (jsonAuth *JSONPatternMatching) Call(pipeline auth.AuthPipeline, ctx context.Context) (interface{}, error) {
+	authJSON := pipeline.GetAuthorizationJSON()
+
+	for i := range jsonAuth.Rules {
+		rule := &jsonAuth.Rules[i]
+
+		authorized, err := rule.EvaluateFor(authJSON)
+		if err != nil {
+			return false, err
+		}
+		if !authorized {
+			return false, fmt.Errorf("Unauthorized")
+		}
+	}
+
+	return true, nil
+}
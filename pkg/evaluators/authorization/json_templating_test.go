@@ -0,0 +1,64 @@
+package authorization
+
+import (
+	gojson "encoding/json"
+	"testing"
+
+	mock_auth "github.com/kuadrant/authorino/pkg/auth/mocks"
+	"github.com/kuadrant/authorino/pkg/json"
+
+	. "github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+func TestNewJSONPatternMatchingRejectsMalformedTemplate(t *testing.T) {
+	_, err := NewJSONPatternMatching([]json.JSONPatternMatchingRule{
+		{Selector: "auth.identity.sub", Operator: "eq", Value: "{{ .broken"},
+	})
+	assert.ErrorContains(t, err, "")
+}
+
+func TestCallWithTemplatedRuleValue(t *testing.T) {
+	ctrl := NewController(t)
+	defer ctrl.Finish()
+
+	authJSON, _ := gojson.Marshal(map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{"sub": "alice"},
+		},
+		"context": map[string]interface{}{
+			"match": map[string]interface{}{
+				"regexp_capture_groups": []string{"alice", "widgets"},
+			},
+		},
+	})
+
+	pipelineMock := mock_auth.NewMockAuthPipeline(ctrl)
+	pipelineMock.EXPECT().GetAuthorizationJSON().Return(string(authJSON)).AnyTimes()
+
+	jsonAuth, err := NewJSONPatternMatching([]json.JSONPatternMatchingRule{
+		{
+			Selector: "auth.identity.sub",
+			Operator: "eq",
+			Value:    "{{ index .context.match.regexp_capture_groups 0 }}",
+		},
+	})
+	assert.NilError(t, err)
+
+	authorized, err := jsonAuth.Call(pipelineMock, nil)
+	assert.Check(t, authorized.(bool))
+	assert.Check(t, err == nil)
+
+	jsonAuth, err = NewJSONPatternMatching([]json.JSONPatternMatchingRule{
+		{
+			Selector: "auth.identity.sub",
+			Operator: "eq",
+			Value:    "{{ index .context.match.regexp_capture_groups 1 }}",
+		},
+	})
+	assert.NilError(t, err)
+
+	authorized, err = jsonAuth.Call(pipelineMock, nil)
+	assert.Check(t, !authorized.(bool))
+	assert.Error(t, err, "Unauthorized")
+}
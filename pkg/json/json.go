@@ -0,0 +1,69 @@
+package json
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/tidwall/gjson"
+)
+
+// JSONPatternMatchingRule represents a single condition to be evaluated against a JSON object: the value
+// addressed by Selector (a gjson path, e.g. "auth.identity.sub") is compared to Value using Operator.
+//
+// Value can itself be expressed as a Go text/template (see NewJSONTemplate), rendered against the same
+// JSON object Selector is evaluated against, so a rule can assert a value against data resolved elsewhere
+// in the object instead of a hardcoded literal (e.g. comparing two claims, or a `context.match` capture
+// group). Values with no template actions are treated as plain literals.
+type JSONPatternMatchingRule struct {
+	Selector string `yaml:"selector"`
+	Operator string `yaml:"operator"`
+	Value    string `yaml:"value"`
+
+	valueTemplate CachedTemplate
+}
+
+// Compile compiles Value as a template, so config loading can reject a malformed template up front instead
+// of it surfacing as a runtime error on live traffic. Safe to call even when Value has no template actions.
+func (rule *JSONPatternMatchingRule) Compile() error {
+	return rule.valueTemplate.Compile(rule.Value)
+}
+
+// EvaluateFor evaluates the rule against jsonData, a JSON-encoded object.
+func (rule *JSONPatternMatchingRule) EvaluateFor(jsonData string) (bool, error) {
+	value := gjson.Get(jsonData, rule.Selector)
+
+	expected, err := rule.renderedValue(jsonData)
+	if err != nil {
+		return false, err
+	}
+
+	switch rule.Operator {
+	case "eq":
+		return value.String() == expected, nil
+	case "neq":
+		return value.String() != expected, nil
+	case "incl":
+		return includes(value, expected), nil
+	case "excl":
+		return !includes(value, expected), nil
+	case "matches":
+		return regexp.MatchString(expected, value.String())
+	default:
+		return false, fmt.Errorf("unknown operator: %s", rule.Operator)
+	}
+}
+
+// renderedValue compiles Value as a template on first use and caches it on the rule, falling back to the
+// literal string when it has no template actions.
+func (rule *JSONPatternMatchingRule) renderedValue(jsonData string) (string, error) {
+	return rule.valueTemplate.Render(rule.Value, jsonData)
+}
+
+func includes(value gjson.Result, expected string) bool {
+	for _, item := range value.Array() {
+		if item.String() == expected {
+			return true
+		}
+	}
+	return false
+}
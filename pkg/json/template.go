@@ -0,0 +1,86 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// JSONTemplate wraps a string that may be expressed as a Go text/template, to be rendered against a JSON
+// object. Values with no template actions are kept as plain literals and rendered as-is, at no parsing cost.
+type JSONTemplate struct {
+	raw      string
+	compiled *template.Template
+}
+
+// NewJSONTemplate parses value as a Go text/template, to be compiled once (typically at config load time)
+// and cached for repeated rendering. Values with no template actions ("{{") are not parsed at all. Capture
+// groups and other slices can be indexed with the builtin `index` function, e.g.
+// `{{ index .context.match.regexp_capture_groups 0 }}`.
+func NewJSONTemplate(value string) (*JSONTemplate, error) {
+	jsonTemplate := &JSONTemplate{raw: value}
+
+	if !strings.Contains(value, "{{") {
+		return jsonTemplate, nil
+	}
+
+	compiled, err := template.New("value").Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	jsonTemplate.compiled = compiled
+
+	return jsonTemplate, nil
+}
+
+// Render evaluates the template against jsonData, a JSON-encoded object, returning the raw value unmodified
+// when it was not compiled as a template.
+func (t *JSONTemplate) Render(jsonData string) (string, error) {
+	if t.compiled == nil {
+		return t.raw, nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	if err := t.compiled.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+// CachedTemplate compiles a value as a JSONTemplate once and reuses it for every subsequent Render, so
+// callers that hold on to the struct (e.g. a rule or header config parsed once at config load and
+// evaluated per request) only pay the compilation cost once. Compile should be called explicitly at config
+// load time, so a malformed template is rejected then rather than surfacing as a runtime error on live
+// traffic; Render falls back to compiling on first use for callers that skip that step.
+type CachedTemplate struct {
+	compileOnce sync.Once
+	template    *JSONTemplate
+	compileErr  error
+}
+
+// Compile parses value as a JSONTemplate and caches the result. It is safe to call more than once (or
+// concurrently from Render) - only the first call compiles.
+func (c *CachedTemplate) Compile(value string) error {
+	c.compileOnce.Do(func() {
+		c.template, c.compileErr = NewJSONTemplate(value)
+	})
+
+	return c.compileErr
+}
+
+// Render compiles value on its first call (see Compile) and renders it against jsonData.
+func (c *CachedTemplate) Render(value string, jsonData string) (string, error) {
+	if err := c.Compile(value); err != nil {
+		return "", err
+	}
+
+	return c.template.Render(jsonData)
+}
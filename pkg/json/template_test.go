@@ -0,0 +1,54 @@
+package json
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestNewJSONTemplateLiteral(t *testing.T) {
+	tmpl, err := NewJSONTemplate("plain-value")
+	assert.NilError(t, err)
+
+	rendered, err := tmpl.Render(`{"foo":"bar"}`)
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, "plain-value")
+}
+
+func TestNewJSONTemplateRendersAgainstJSON(t *testing.T) {
+	tmpl, err := NewJSONTemplate("hello {{ .name }}, group {{ index .groups 0 }}")
+	assert.NilError(t, err)
+
+	rendered, err := tmpl.Render(`{"name":"alice","groups":["admins","users"]}`)
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, "hello alice, group admins")
+}
+
+func TestNewJSONTemplateParseError(t *testing.T) {
+	_, err := NewJSONTemplate("{{ .broken")
+	assert.ErrorContains(t, err, "")
+}
+
+func TestCachedTemplateCompileIsEager(t *testing.T) {
+	var cached CachedTemplate
+
+	err := cached.Compile("{{ .broken")
+	assert.ErrorContains(t, err, "")
+
+	// the same error is returned on every subsequent call, without re-parsing
+	_, renderErr := cached.Render("{{ .broken", `{}`)
+	assert.Error(t, renderErr, err.Error())
+}
+
+func TestCachedTemplateRenderReusesCompiledTemplate(t *testing.T) {
+	var cached CachedTemplate
+
+	first, err := cached.Render("{{ .name }}", `{"name":"alice"}`)
+	assert.NilError(t, err)
+	assert.Equal(t, first, "alice")
+
+	// value is ignored on subsequent calls; the template compiled on the first call is reused
+	second, err := cached.Render("{{ .other }}", `{"name":"bob"}`)
+	assert.NilError(t, err)
+	assert.Equal(t, second, "bob")
+}
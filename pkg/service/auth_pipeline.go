@@ -1,19 +1,98 @@
 package service
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
 	"sync"
 
 	"github.com/3scale-labs/authorino/pkg/common"
 	"github.com/3scale-labs/authorino/pkg/config"
+	envoy_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	authjson "github.com/kuadrant/authorino/pkg/json"
 	"golang.org/x/net/context"
+	rpc_code "google.golang.org/genproto/googleapis/rpc/code"
+	rpc_status "google.golang.org/genproto/googleapis/rpc/status"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 var (
 	authCtxLog = ctrl.Log.WithName("Authorino").WithName("AuthPipeline")
+
+	errUnmatchedRequest = fmt.Errorf("Unauthorized")
 )
 
+// MatchContext carries the route-derived data obtained by matching the request URL against the API's
+// configured pattern (config.APIConfig.MatchConfig). It is exposed to authorization rules under the
+// `context.match` JSON selector, e.g. `context.match.regexp_capture_groups.0` or `context.match.url.path`.
+type MatchContext struct {
+	URL                 *url.URL `json:"-"`
+	RegexpCaptureGroups []string `json:"regexp_capture_groups,omitempty"`
+}
+
+// MarshalJSON renders the URL with lower-cased field names, so it reads the same way as every other
+// selector path used by JSONPatternMatching (e.g. `context.match.url.host`).
+func (m *MatchContext) MarshalJSON() ([]byte, error) {
+	type matchContext struct {
+		URL *struct {
+			Scheme string `json:"scheme,omitempty"`
+			Host   string `json:"host,omitempty"`
+			Path   string `json:"path,omitempty"`
+			Query  string `json:"query,omitempty"`
+		} `json:"url,omitempty"`
+		RegexpCaptureGroups []string `json:"regexp_capture_groups,omitempty"`
+	}
+
+	out := matchContext{RegexpCaptureGroups: m.RegexpCaptureGroups}
+
+	if m.URL != nil {
+		out.URL = &struct {
+			Scheme string `json:"scheme,omitempty"`
+			Host   string `json:"host,omitempty"`
+			Path   string `json:"path,omitempty"`
+			Query  string `json:"query,omitempty"`
+		}{
+			Scheme: m.URL.Scheme,
+			Host:   m.URL.Host,
+			Path:   m.URL.Path,
+			Query:  m.URL.RawQuery,
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// ResponseConfig represents a single header to be injected into the upstream request once authorization
+// succeeds. Value can be a plain literal or a Go template (same engine used by JSONPatternMatching rule
+// values, see pkg/json.JSONTemplate) evaluated against the authorization JSON, so a selector path such as
+// `{{ .auth.identity.sub }}` can be used to pull data out of auth.identity, auth.metadata or context.match.
+type ResponseConfig struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+
+	valueTemplate authjson.CachedTemplate
+}
+
+// NewResponseConfig compiles Value as a template up front (see authjson.CachedTemplate.Compile), so a
+// malformed template is rejected at config load instead of silently skipping the header at request time.
+// Config loading should build a ResponseConfig through this constructor rather than the struct literal.
+func NewResponseConfig(name, value string) (*ResponseConfig, error) {
+	responseConfig := &ResponseConfig{Name: name, Value: value}
+
+	if err := responseConfig.valueTemplate.Compile(value); err != nil {
+		return nil, err
+	}
+
+	return responseConfig, nil
+}
+
+func (responseConfig *ResponseConfig) renderedValue(authJSON string) (string, error) {
+	return responseConfig.valueTemplate.Render(responseConfig.Value, authJSON)
+}
+
 type EvaluationResponse struct {
 	Evaluator common.AuthConfigEvaluator
 	Object    interface{}
@@ -43,6 +122,9 @@ type AuthPipeline struct {
 	Identity      map[*config.IdentityConfig]interface{}
 	Metadata      map[*config.MetadataConfig]interface{}
 	Authorization map[*config.AuthorizationConfig]interface{}
+
+	Match    *MatchContext
+	Response map[*ResponseConfig]string
 }
 
 // NewAuthPipeline creates an AuthPipeline instance
@@ -117,6 +199,43 @@ func (pipeline *AuthPipeline) evaluateAnyAuthConfig(authConfigs []common.AuthCon
 	})
 }
 
+// evaluateMatchConfig compiles and matches the API's configured URL pattern (config.APIConfig.MatchConfig)
+// against the request path, populating pipeline.Match with the parsed URL and any regexp capture groups.
+// The request is short-circuited to Unauthorized whenever a MatchConfig is set but the pattern does not match.
+func (pipeline *AuthPipeline) evaluateMatchConfig() error {
+	matchConfig := pipeline.API.MatchConfig
+	if matchConfig == nil {
+		return nil
+	}
+
+	http := pipeline.GetHttp()
+
+	reqURL, err := url.Parse(http.GetPath())
+	if err != nil {
+		return err
+	}
+	reqURL.Scheme = http.GetScheme()
+	reqURL.Host = http.GetHost()
+
+	pattern, err := regexp.Compile(matchConfig.Pattern)
+	if err != nil {
+		return err
+	}
+
+	captureGroups := pattern.FindStringSubmatch(reqURL.Path)
+	if captureGroups == nil {
+		authCtxLog.Info("Match", "config", matchConfig, "path", reqURL.Path, "error", "pattern did not match")
+		return errUnmatchedRequest
+	}
+
+	pipeline.Match = &MatchContext{
+		URL:                 reqURL,
+		RegexpCaptureGroups: captureGroups[1:],
+	}
+
+	return nil
+}
+
 func (pipeline *AuthPipeline) evaluateIdentityConfigs() error {
 	configs := pipeline.API.IdentityConfigs
 	respChannel := make(chan EvaluationResponse, len(configs))
@@ -193,8 +312,44 @@ func (pipeline *AuthPipeline) evaluateAuthorizationConfigs() error {
 	return nil
 }
 
-// Evaluate evaluates all steps of the auth pipeline (identity → metadata → policy enforcement)
+// evaluateResponseConfigs evaluates the API's configured response headers (config.APIConfig.ResponseConfigs)
+// against the authorization JSON, caching the rendered values in pipeline.Response. A header whose value
+// fails to render is logged and skipped, rather than failing the whole request.
+func (pipeline *AuthPipeline) evaluateResponseConfigs() error {
+	responseConfigs := pipeline.API.ResponseConfigs
+	if len(responseConfigs) == 0 {
+		return nil
+	}
+
+	authJSON, err := pipeline.authorizationJSON()
+	if err != nil {
+		return err
+	}
+
+	pipeline.Response = make(map[*ResponseConfig]string, len(responseConfigs))
+
+	for i := range responseConfigs {
+		responseConfig := &responseConfigs[i]
+
+		value, err := responseConfig.renderedValue(authJSON)
+		if err != nil {
+			authCtxLog.Info("Response", "config", responseConfig, "error", err)
+			continue
+		}
+
+		pipeline.Response[responseConfig] = value
+	}
+
+	return nil
+}
+
+// Evaluate evaluates all steps of the auth pipeline (match → identity → metadata → policy enforcement → response)
 func (pipeline *AuthPipeline) Evaluate() error {
+	// match
+	if err := pipeline.evaluateMatchConfig(); err != nil {
+		return err
+	}
+
 	// identity
 	if err := pipeline.evaluateIdentityConfigs(); err != nil {
 		return err
@@ -208,6 +363,11 @@ func (pipeline *AuthPipeline) Evaluate() error {
 		return err
 	}
 
+	// response (header injection)
+	if err := pipeline.evaluateResponseConfigs(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -259,13 +419,90 @@ func (pipeline *AuthPipeline) GetDataForAuthorization() interface{} {
 	}
 	authData["metadata"] = resolvedMetadata
 
+	// the envoy attribute context is embedded so its own fields (request, source, destination...) stay
+	// at the top of "context", with "match" added alongside them for JSONPatternMatching selectors such
+	// as `context.match.regexp_capture_groups.0` or `context.match.url.host`
+	type authorizationContext struct {
+		*envoy_auth.AttributeContext
+		Match *MatchContext `json:"match,omitempty"`
+	}
+
 	type authorizationData struct {
-		Context  *envoy_auth.AttributeContext `json:"context"`
-		AuthData map[string]interface{}       `json:"auth"`
+		Context  *authorizationContext  `json:"context"`
+		AuthData map[string]interface{} `json:"auth"`
 	}
 
 	return &authorizationData{
-		Context:  pipeline.GetRequest().Attributes,
+		Context: &authorizationContext{
+			AttributeContext: pipeline.GetRequest().Attributes,
+			Match:            pipeline.Match,
+		},
 		AuthData: authData,
 	}
-}
\ No newline at end of file
+}
+
+// authorizationJSON marshals GetDataForAuthorization, propagating any marshalling error to the caller.
+// Most callers want GetAuthorizationJSON instead; this is for the few (e.g. evaluateResponseConfigs) that
+// need to fail the request rather than fall back to an empty string.
+func (pipeline *AuthPipeline) authorizationJSON() (string, error) {
+	authJSON, err := json.Marshal(pipeline.GetDataForAuthorization())
+	if err != nil {
+		return "", err
+	}
+	return string(authJSON), nil
+}
+
+// GetAuthorizationJSON returns the JSON-encoded representation of GetDataForAuthorization, as consumed by
+// JSONPatternMatching selectors. Implements auth.AuthPipeline. A marshalling error is logged and an empty
+// string returned, since the interface has no room to propagate one.
+func (pipeline *AuthPipeline) GetAuthorizationJSON() string {
+	authJSON, err := pipeline.authorizationJSON()
+	if err != nil {
+		authCtxLog.Info("AuthorizationJSON", "error", err)
+		return ""
+	}
+	return authJSON
+}
+
+// GetResponseHeaders returns the headers resolved by evaluateResponseConfigs, ready to be carried back to
+// Envoy in the ext_authz Check response (envoy_auth.OkHttpResponse.Headers).
+func (pipeline *AuthPipeline) GetResponseHeaders() []*envoy_core.HeaderValueOption {
+	headers := make([]*envoy_core.HeaderValueOption, 0, len(pipeline.Response))
+
+	for responseConfig, value := range pipeline.Response {
+		headers = append(headers, &envoy_core.HeaderValueOption{
+			Header: &envoy_core.HeaderValue{
+				Key:   responseConfig.Name,
+				Value: value,
+			},
+		})
+	}
+
+	return headers
+}
+
+// Check runs Evaluate and builds the ext_authz response returned to Envoy: on success, an OkHttpResponse
+// carrying the headers resolved by GetResponseHeaders; on failure, a DeniedHttpResponse.
+func (pipeline *AuthPipeline) Check() *envoy_auth.CheckResponse {
+	if err := pipeline.Evaluate(); err != nil {
+		authCtxLog.Info("Check", "status", "Unauthorized", "error", err)
+
+		return &envoy_auth.CheckResponse{
+			Status: &rpc_status.Status{Code: int32(rpc_code.Code_UNAUTHENTICATED)},
+			HttpResponse: &envoy_auth.CheckResponse_DeniedResponse{
+				DeniedResponse: &envoy_auth.DeniedHttpResponse{
+					Status: &envoy_type.HttpStatus{Code: envoy_type.StatusCode_Unauthorized},
+				},
+			},
+		}
+	}
+
+	return &envoy_auth.CheckResponse{
+		Status: &rpc_status.Status{Code: int32(rpc_code.Code_OK)},
+		HttpResponse: &envoy_auth.CheckResponse_OkResponse{
+			OkResponse: &envoy_auth.OkHttpResponse{
+				Headers: pipeline.GetResponseHeaders(),
+			},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestMatchContextMarshalJSON(t *testing.T) {
+	match := &MatchContext{
+		URL: &url.URL{
+			Scheme:   "https",
+			Host:     "api.example.com",
+			Path:     "/widgets/42",
+			RawQuery: "verbose=true",
+		},
+		RegexpCaptureGroups: []string{"widgets", "42"},
+	}
+
+	out, err := match.MarshalJSON()
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), `{"url":{"scheme":"https","host":"api.example.com","path":"/widgets/42","query":"verbose=true"},"regexp_capture_groups":["widgets","42"]}`)
+}
+
+func TestMatchContextMarshalJSONWithoutURL(t *testing.T) {
+	match := &MatchContext{RegexpCaptureGroups: []string{"42"}}
+
+	out, err := match.MarshalJSON()
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), `{"regexp_capture_groups":["42"]}`)
+}
+
+func TestNewResponseConfigRejectsMalformedTemplate(t *testing.T) {
+	_, err := NewResponseConfig("X-User", "{{ .broken")
+	assert.ErrorContains(t, err, "")
+}
+
+func TestResponseConfigRenderedValue(t *testing.T) {
+	header, err := NewResponseConfig("X-User", "{{ .auth.identity.sub }}")
+	assert.NilError(t, err)
+
+	value, err := header.renderedValue(`{"auth":{"identity":{"sub":"alice"}}}`)
+	assert.NilError(t, err)
+	assert.Equal(t, value, "alice")
+}
+
+func TestGetResponseHeaders(t *testing.T) {
+	userHeader, err := NewResponseConfig("X-User", "{{ .auth.identity.sub }}")
+	assert.NilError(t, err)
+
+	pipeline := &AuthPipeline{
+		Response: map[*ResponseConfig]string{
+			userHeader: "alice",
+		},
+	}
+
+	headers := pipeline.GetResponseHeaders()
+	assert.Equal(t, len(headers), 1)
+	assert.Equal(t, headers[0].Header.Key, "X-User")
+	assert.Equal(t, headers[0].Header.Value, "alice")
+}